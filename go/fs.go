@@ -0,0 +1,257 @@
+package originFSKit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClientFS adapts a Client to the io/fs.FS family of interfaces, rooted
+// at the given base path within the rotur filesystem.
+type ClientFS struct {
+	c    *Client
+	base string
+}
+
+// FS returns an io/fs.FS view of the client rooted at "/".
+func (c *Client) FS() fs.FS {
+	return ClientFS{c: c, base: "/"}
+}
+
+var (
+	_ fs.FS         = ClientFS{}
+	_ fs.ReadDirFS  = ClientFS{}
+	_ fs.StatFS     = ClientFS{}
+	_ fs.ReadFileFS = ClientFS{}
+	_ fs.SubFS      = ClientFS{}
+)
+
+func (cfs ClientFS) resolve(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	p := cfs.base
+	if name != "." {
+		if !strings.HasSuffix(p, "/") {
+			p += "/"
+		}
+		p += name
+	}
+	return strings.ToLower(cfs.c.JoinPath(p)), nil
+}
+
+func (cfs ClientFS) Sub(dir string) (fs.FS, error) {
+	p, err := cfs.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	return ClientFS{c: cfs.c, base: p}, nil
+}
+
+func (cfs ClientFS) Open(name string) (fs.File, error) {
+	p, err := cfs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "." {
+		return &clientDir{cfs: cfs, path: p, name: "."}, nil
+	}
+
+	info, err := cfs.statPath(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: translateErr(err)}
+	}
+
+	if info.IsDir() {
+		return &clientDir{cfs: cfs, path: p, name: info.Name()}, nil
+	}
+
+	data, err := cfs.c.ReadFileContent(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: translateErr(err)}
+	}
+
+	return &clientFile{info: info, r: bytes.NewReader([]byte(data))}, nil
+}
+
+func (cfs ClientFS) ReadFile(name string) ([]byte, error) {
+	p, err := cfs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := cfs.c.ReadFileContent(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: translateErr(err)}
+	}
+	return []byte(data), nil
+}
+
+func (cfs ClientFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := cfs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := cfs.statPath(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: translateErr(err)}
+	}
+	return info, nil
+}
+
+func (cfs ClientFS) statPath(p string) (*entryInfo, error) {
+	if p == "/" {
+		return &entryInfo{name: ".", isDir: true}, nil
+	}
+	entry, err := cfs.c.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	return entryInfoFromEntry(entry), nil
+}
+
+func (cfs ClientFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	p, err := cfs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	out, err := cfs.readDirPath(p)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: translateErr(err)}
+	}
+	return out, nil
+}
+
+// readDirPath lists the children of the already-resolved absolute path p,
+// combining the prefix scan in Client.ListDir with a per-entry stat.
+func (cfs ClientFS) readDirPath(p string) ([]fs.DirEntry, error) {
+	children := cfs.c.ListDir(p)
+	prefix := strings.TrimSuffix(p, "/")
+
+	out := make([]fs.DirEntry, 0, len(children))
+	for _, child := range children {
+		entry, err := cfs.c.ReadFile(prefix + "/" + child)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, entryInfoFromEntry(entry))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out, nil
+}
+
+// entryInfo implements both fs.FileInfo and fs.DirEntry over a FileEntry.
+type entryInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+func entryInfoFromEntry(e FileEntry) *entryInfo {
+	name := fmt.Sprint(e[IdxName])
+	typ := fmt.Sprint(e[IdxType])
+	isDir := typ == ".folder"
+	if !isDir {
+		name += typ
+	}
+
+	var size int64
+	switch v := e[IdxSize].(type) {
+	case int:
+		size = int64(v)
+	case int64:
+		size = v
+	case float64:
+		size = int64(v)
+	}
+
+	var modTime time.Time
+	if millis := idxEditedMillis(e); millis != 0 {
+		modTime = time.UnixMilli(millis)
+	}
+
+	return &entryInfo{name: name, size: size, modTime: modTime, isDir: isDir}
+}
+
+func (i *entryInfo) Name() string       { return i.name }
+func (i *entryInfo) Size() int64        { return i.size }
+func (i *entryInfo) ModTime() time.Time { return i.modTime }
+func (i *entryInfo) IsDir() bool        { return i.isDir }
+func (i *entryInfo) Sys() any           { return nil }
+
+func (i *entryInfo) Mode() fs.FileMode {
+	if i.isDir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func (i *entryInfo) Type() fs.FileMode {
+	return i.Mode().Type()
+}
+
+func (i *entryInfo) Info() (fs.FileInfo, error) {
+	return i, nil
+}
+
+// clientFile implements fs.File for a regular file.
+type clientFile struct {
+	info *entryInfo
+	r    *bytes.Reader
+}
+
+func (f *clientFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *clientFile) Read(b []byte) (int, error) { return f.r.Read(b) }
+func (f *clientFile) Close() error               { return nil }
+
+// clientDir implements fs.File (and fs.ReadDirFile) for a directory.
+type clientDir struct {
+	cfs     ClientFS
+	path    string
+	name    string
+	entries []fs.DirEntry
+	read    int
+}
+
+func (d *clientDir) Stat() (fs.FileInfo, error) {
+	return &entryInfo{name: d.name, isDir: true}, nil
+}
+
+func (d *clientDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fmt.Errorf("is a directory")}
+}
+
+func (d *clientDir) Close() error { return nil }
+
+func (d *clientDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		entries, err := d.cfs.readDirPath(d.path)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+	}
+
+	if n <= 0 {
+		out := d.entries[d.read:]
+		d.read = len(d.entries)
+		return out, nil
+	}
+
+	if d.read >= len(d.entries) {
+		return nil, io.EOF
+	}
+
+	end := d.read + n
+	if end > len(d.entries) {
+		end = len(d.entries)
+	}
+	out := d.entries[d.read:end]
+	d.read = end
+	return out, nil
+}
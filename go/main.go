@@ -12,6 +12,7 @@ import (
 	"math/rand"
 	"net/http"
 	"net/url"
+	"os"
 	"path"
 	"strconv"
 	"strings"
@@ -21,6 +22,13 @@ import (
 
 const BaseURL = "https://api.rotur.dev"
 const entrySize = 14
+const pathIndexEndpoint = "/files/path-index"
+
+const defaultRequestTimeout = 30 * time.Second
+
+// errNotModified signals that a conditional request came back 304, i.e. the
+// path index hasn't changed since the last fetch.
+var errNotModified = errors.New("originfskit: not modified")
 
 const (
 	IdxType     = 0
@@ -59,14 +67,30 @@ type GetFilesResponse struct {
 }
 
 type Client struct {
-	Token    string
-	HTTP     *http.Client
-	mu       sync.Mutex
+	Token string
+	HTTP  *http.Client
+
+	// RequestTimeout bounds each individual HTTP round trip made through
+	// request. It applies even when a caller-supplied context.Context has
+	// no deadline of its own. Zero means defaultRequestTimeout.
+	RequestTimeout time.Duration
+
+	// RefreshInterval, if non-zero, is the polling period Refresh uses for
+	// its background index-refresh goroutine.
+	RefreshInterval time.Duration
+
+	muCh     chan struct{}
 	index    map[string]string
 	entries  map[string]FileEntry
 	dirty    []UpdateChange
 	loaded   bool
 	username string
+
+	indexETag         string
+	indexLastModified string
+
+	subsMu sync.Mutex
+	subs   []chan PathEvent
 }
 
 func NewClient(token string) *Client {
@@ -75,11 +99,30 @@ func NewClient(token string) *Client {
 		HTTP: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		muCh:    make(chan struct{}, 1),
 		index:   map[string]string{},
 		entries: map[string]FileEntry{},
 	}
 }
 
+// lock acquires the client's state lock, unblocking early with ctx.Err() if
+// ctx is canceled before the lock is free. Paired with unlock, this keeps a
+// caller's cancellation honored even while another goroutine is holding the
+// lock for an in-flight request, instead of blocking until that request
+// finishes.
+func (c *Client) lock(ctx context.Context) error {
+	select {
+	case c.muCh <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) unlock() {
+	<-c.muCh
+}
+
 func randomString(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, length)
@@ -96,11 +139,17 @@ func generateUUID(username string) string {
 }
 
 func (c *Client) GetUuid(p string) (string, error) {
-	if err := c.loadIndex(); err != nil {
+	return c.GetUuidContext(context.Background(), p)
+}
+
+func (c *Client) GetUuidContext(ctx context.Context, p string) (string, error) {
+	if err := c.loadIndexContext(ctx); err != nil {
 		return "", err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if err := c.lock(ctx); err != nil {
+		return "", err
+	}
+	defer c.unlock()
 	uuid, ok := c.index[strings.ToLower(p)]
 	if !ok {
 		return "", errors.New("not found")
@@ -109,12 +158,18 @@ func (c *Client) GetUuid(p string) (string, error) {
 }
 
 func (c *Client) GetPath(uuid string) (string, error) {
-	if err := c.loadIndex(); err != nil {
+	return c.GetPathContext(context.Background(), uuid)
+}
+
+func (c *Client) GetPathContext(ctx context.Context, uuid string) (string, error) {
+	if err := c.loadIndexContext(ctx); err != nil {
 		return "", err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if err := c.ensureEntry(uuid); err != nil {
+	if err := c.lock(ctx); err != nil {
+		return "", err
+	}
+	defer c.unlock()
+	if err := c.ensureEntryContext(ctx, uuid); err != nil {
 		return "", err
 	}
 	entry, ok := c.entries[uuid]
@@ -124,7 +179,17 @@ func (c *Client) GetPath(uuid string) (string, error) {
 	return entryToPath(entry), nil
 }
 
-func (c *Client) request(method, p string, body any, out any) error {
+// requestTimeout returns the per-request deadline to apply on top of a
+// caller's context, falling back to defaultRequestTimeout when
+// RequestTimeout is unset.
+func (c *Client) requestTimeout() time.Duration {
+	if c.RequestTimeout > 0 {
+		return c.RequestTimeout
+	}
+	return defaultRequestTimeout
+}
+
+func (c *Client) request(ctx context.Context, method, p string, body any, out any) error {
 	u, _ := url.Parse(BaseURL + p)
 	q := u.Query()
 	q.Set("auth", c.Token)
@@ -136,7 +201,7 @@ func (c *Client) request(method, p string, body any, out any) error {
 		r = bytes.NewReader(b)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, c.requestTimeout())
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
@@ -148,12 +213,45 @@ func (c *Client) request(method, p string, body any, out any) error {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	if p == pathIndexEndpoint {
+		if err := c.lock(ctx); err != nil {
+			return err
+		}
+		etag, lastModified := c.indexETag, c.indexLastModified
+		c.unlock()
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("request failed: %w", ctxErr)
+		}
 		return fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if p == pathIndexEndpoint {
+		if err := c.lock(ctx); err == nil {
+			if etag := resp.Header.Get("ETag"); etag != "" {
+				c.indexETag = etag
+			}
+			if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+				c.indexLastModified = lastModified
+			}
+			c.unlock()
+		}
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return errNotModified
+	}
+
 	if resp.StatusCode != 200 {
 		b, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("http %d: %s", resp.StatusCode, string(b))
@@ -166,20 +264,28 @@ func (c *Client) request(method, p string, body any, out any) error {
 }
 
 func (c *Client) loadIndex() error {
-	c.mu.Lock()
-	if c.loaded {
-		c.mu.Unlock()
+	return c.loadIndexContext(context.Background())
+}
+
+func (c *Client) loadIndexContext(ctx context.Context) error {
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	loaded := c.loaded
+	c.unlock()
+	if loaded {
 		return nil
 	}
-	c.mu.Unlock()
 
 	var raw map[string]any
-	if err := c.request("GET", "/files/path-index", nil, &raw); err != nil {
+	if err := c.request(ctx, "GET", pathIndexEndpoint, nil, &raw); err != nil {
 		return err
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	defer c.unlock()
 
 	if c.loaded {
 		return nil
@@ -205,12 +311,16 @@ func (c *Client) loadIndex() error {
 }
 
 func (c *Client) ensureEntry(uuid string) error {
+	return c.ensureEntryContext(context.Background(), uuid)
+}
+
+func (c *Client) ensureEntryContext(ctx context.Context, uuid string) error {
 	if _, ok := c.entries[uuid]; ok {
 		return nil
 	}
 
 	var entry FileEntry
-	if err := c.request("GET", "/files/by-uuid?uuid="+uuid, nil, &entry); err != nil {
+	if err := c.request(ctx, "GET", "/files/by-uuid?uuid="+uuid, nil, &entry); err != nil {
 		return err
 	}
 
@@ -218,6 +328,42 @@ func (c *Client) ensureEntry(uuid string) error {
 	return nil
 }
 
+// ensureEntriesContext fetches every uuid in uuids that isn't already cached, in a
+// single batched request instead of one round trip per uuid.
+func (c *Client) ensureEntriesContext(ctx context.Context, uuids []string) error {
+	var missing []string
+	for _, uuid := range uuids {
+		if _, ok := c.entries[uuid]; !ok {
+			missing = append(missing, uuid)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	fetched, err := c.fetchEntriesContext(ctx, missing)
+	if err != nil {
+		return err
+	}
+	for uuid, entry := range fetched {
+		c.entries[uuid] = entry
+	}
+	return nil
+}
+
+// fetchEntriesContext unconditionally fetches the given uuids in a single
+// batched request, regardless of what is already cached.
+func (c *Client) fetchEntriesContext(ctx context.Context, uuids []string) (map[string]FileEntry, error) {
+	if len(uuids) == 0 {
+		return map[string]FileEntry{}, nil
+	}
+	var res GetFilesResponse
+	if err := c.request(ctx, "POST", "/files/by-uuids", GetFilesRequest{UUIDs: uuids}, &res); err != nil {
+		return nil, err
+	}
+	return res.Files, nil
+}
+
 func entryToPath(e FileEntry) string {
 	location := fmt.Sprint(e[IdxLocation])
 	name := fmt.Sprint(e[IdxName])
@@ -239,18 +385,54 @@ func cleanPath(p string) string {
 	return path.Clean("/" + p)
 }
 
+// translateErr maps the plain sentinel errors used internally (e.g. the
+// "not found" returned when a path isn't in the index) to their stdlib
+// equivalents, so callers can use errors.Is against os/io/fs errors
+// regardless of which adapter (ClientFS, WebDAVFS, ...) they're using.
+func translateErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch err.Error() {
+	case "not found":
+		return os.ErrNotExist
+	default:
+		return err
+	}
+}
+
 func clone(e FileEntry) FileEntry {
 	out := make(FileEntry, len(e))
 	copy(out, e)
 	return out
 }
 
+// idxEditedMillis reads IdxEdited as a Unix-millisecond timestamp,
+// tolerating both the int64 form set locally and the float64 form that
+// comes back from JSON decoding.
+func idxEditedMillis(e FileEntry) int64 {
+	switch v := e[IdxEdited].(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
 func (c *Client) ListPaths() ([]string, error) {
-	if err := c.loadIndex(); err != nil {
+	return c.ListPathsContext(context.Background())
+}
+
+func (c *Client) ListPathsContext(ctx context.Context) ([]string, error) {
+	if err := c.loadIndexContext(ctx); err != nil {
 		return nil, err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if err := c.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer c.unlock()
 	out := make([]string, 0, len(c.index))
 	for p := range c.index {
 		out = append(out, p)
@@ -259,32 +441,44 @@ func (c *Client) ListPaths() ([]string, error) {
 }
 
 func (c *Client) ReadFile(p string) (FileEntry, error) {
-	if err := c.loadIndex(); err != nil {
+	return c.ReadFileContext(context.Background(), p)
+}
+
+func (c *Client) ReadFileContext(ctx context.Context, p string) (FileEntry, error) {
+	if err := c.loadIndexContext(ctx); err != nil {
 		return nil, err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if err := c.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer c.unlock()
 	uuid, ok := c.index[strings.ToLower(p)]
 	if !ok {
 		return nil, errors.New("not found")
 	}
-	if err := c.ensureEntry(uuid); err != nil {
+	if err := c.ensureEntryContext(ctx, uuid); err != nil {
 		return nil, err
 	}
 	return clone(c.entries[uuid]), nil
 }
 
 func (c *Client) ReadFileContent(p string) (string, error) {
-	if err := c.loadIndex(); err != nil {
+	return c.ReadFileContentContext(context.Background(), p)
+}
+
+func (c *Client) ReadFileContentContext(ctx context.Context, p string) (string, error) {
+	if err := c.loadIndexContext(ctx); err != nil {
 		return "", err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if err := c.lock(ctx); err != nil {
+		return "", err
+	}
+	defer c.unlock()
 	uuid, ok := c.index[strings.ToLower(p)]
 	if !ok {
 		return "", errors.New("not found")
 	}
-	if err := c.ensureEntry(uuid); err != nil {
+	if err := c.ensureEntryContext(ctx, uuid); err != nil {
 		return "", err
 	}
 	data, ok := c.entries[uuid][IdxData].(string)
@@ -295,17 +489,23 @@ func (c *Client) ReadFileContent(p string) (string, error) {
 }
 
 func (c *Client) WriteFile(p string, data string) error {
-	if err := c.loadIndex(); err != nil {
+	return c.WriteFileContext(context.Background(), p, data)
+}
+
+func (c *Client) WriteFileContext(ctx context.Context, p string, data string) error {
+	if err := c.loadIndexContext(ctx); err != nil {
 		return err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	defer c.unlock()
 	now := time.Now().UnixMilli()
 	uuid, ok := c.index[strings.ToLower(p)]
 	if !ok {
 		return errors.New("create via CreateFile")
 	}
-	if err := c.ensureEntry(uuid); err != nil {
+	if err := c.ensureEntryContext(ctx, uuid); err != nil {
 		return err
 	}
 	e := c.entries[uuid]
@@ -352,12 +552,18 @@ func (c *Client) createFolders(dir string) error {
 }
 
 func (c *Client) CreateFile(p string, data string) error {
+	return c.CreateFileContext(context.Background(), p, data)
+}
+
+func (c *Client) CreateFileContext(ctx context.Context, p string, data string) error {
 	p = strings.ToLower(p)
-	if err := c.loadIndex(); err != nil {
+	if err := c.loadIndexContext(ctx); err != nil {
+		return err
+	}
+	if err := c.lock(ctx); err != nil {
 		return err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 	now := time.Now().UnixMilli()
 	dir, file := path.Split(p)
 	ext := path.Ext(file)
@@ -384,12 +590,18 @@ func (c *Client) CreateFile(p string, data string) error {
 }
 
 func (c *Client) CreateFolder(p string) error {
+	return c.CreateFolderContext(context.Background(), p)
+}
+
+func (c *Client) CreateFolderContext(ctx context.Context, p string) error {
 	p = strings.ToLower(p)
-	if err := c.loadIndex(); err != nil {
+	if err := c.loadIndexContext(ctx); err != nil {
+		return err
+	}
+	if err := c.lock(ctx); err != nil {
 		return err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 	now := time.Now().UnixMilli()
 	dir, file := path.Split(p)
 	ext := path.Ext(file)
@@ -416,12 +628,16 @@ func (c *Client) CreateFolder(p string) error {
 }
 
 func (c *Client) ListDir(p string) []string {
+	return c.ListDirContext(context.Background(), p)
+}
+
+func (c *Client) ListDirContext(ctx context.Context, p string) []string {
 	p = strings.TrimSuffix(strings.ToLower(p), "/")
 	if p == "" {
 		p = "/"
 	}
 
-	paths, err := c.ListPaths()
+	paths, err := c.ListPathsContext(ctx)
 	if err != nil {
 		return []string{}
 	}
@@ -452,12 +668,18 @@ func (c *Client) ListDir(p string) []string {
 }
 
 func (c *Client) Remove(p string) error {
+	return c.RemoveContext(context.Background(), p)
+}
+
+func (c *Client) RemoveContext(ctx context.Context, p string) error {
 	p = strings.ToLower(p)
-	if err := c.loadIndex(); err != nil {
+	if err := c.loadIndexContext(ctx); err != nil {
 		return err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	defer c.unlock()
 	uuid, ok := c.index[p]
 	if !ok {
 		return errors.New("not found")
@@ -469,12 +691,18 @@ func (c *Client) Remove(p string) error {
 }
 
 func (c *Client) Exists(p string) bool {
+	return c.ExistsContext(context.Background(), p)
+}
+
+func (c *Client) ExistsContext(ctx context.Context, p string) bool {
 	p = strings.ToLower(p)
-	if err := c.loadIndex(); err != nil {
+	if err := c.loadIndexContext(ctx); err != nil {
 		return false
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if err := c.lock(ctx); err != nil {
+		return false
+	}
+	defer c.unlock()
 	_, ok := c.index[p]
 	return ok
 }
@@ -488,16 +716,22 @@ func (c *Client) JoinPath(elem ...string) string {
 }
 
 func (c *Client) Rename(oldPath, newPath string) error {
-	if err := c.loadIndex(); err != nil {
+	return c.RenameContext(context.Background(), oldPath, newPath)
+}
+
+func (c *Client) RenameContext(ctx context.Context, oldPath, newPath string) error {
+	if err := c.loadIndexContext(ctx); err != nil {
 		return err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	defer c.unlock()
 	uuid, ok := c.index[strings.ToLower(oldPath)]
 	if !ok {
 		return errors.New("not found")
 	}
-	if err := c.ensureEntry(uuid); err != nil {
+	if err := c.ensureEntryContext(ctx, uuid); err != nil {
 		return err
 	}
 	e := c.entries[uuid]
@@ -520,12 +754,18 @@ func (c *Client) Rename(oldPath, newPath string) error {
 }
 
 func (c *Client) StatUUID(uuid string) (FileEntry, error) {
-	if err := c.loadIndex(); err != nil {
+	return c.StatUUIDContext(context.Background(), uuid)
+}
+
+func (c *Client) StatUUIDContext(ctx context.Context, uuid string) (FileEntry, error) {
+	if err := c.loadIndexContext(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.lock(ctx); err != nil {
 		return nil, err
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if err := c.ensureEntry(uuid); err != nil {
+	defer c.unlock()
+	if err := c.ensureEntryContext(ctx, uuid); err != nil {
 		return nil, err
 	}
 	e, ok := c.entries[uuid]
@@ -535,15 +775,25 @@ func (c *Client) StatUUID(uuid string) (FileEntry, error) {
 	return clone(e), nil
 }
 
+// Commit flushes mutations accumulated directly on c (outside of a Tx) to
+// the server. It is a compatibility shim equivalent to a single
+// auto-transaction with no conflict checking; use Client.Begin for
+// all-or-nothing semantics.
 func (c *Client) Commit() error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	return c.CommitContext(context.Background())
+}
+
+func (c *Client) CommitContext(ctx context.Context) error {
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	defer c.unlock()
 	if len(c.dirty) == 0 {
 		return nil
 	}
 	req := UpdateFileRequest{Updates: c.dirty}
 	var res UpdateResult
-	if err := c.request("POST", "/files", req, &res); err != nil {
+	if err := c.request(ctx, "POST", "/files", req, &res); err != nil {
 		return err
 	}
 	c.dirty = nil
@@ -0,0 +1,200 @@
+package originFSKit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PathEventType categorizes a PathEvent emitted by Refresh.
+type PathEventType string
+
+const (
+	PathCreated  PathEventType = "created"
+	PathModified PathEventType = "modified"
+	PathDeleted  PathEventType = "deleted"
+)
+
+// PathEvent describes a change discovered by Refresh. Path is empty for a
+// PathModified event reporting an edit to an entry whose path is unchanged.
+type PathEvent struct {
+	Type PathEventType
+	Path string
+	UUID string
+}
+
+// Subscribe returns a channel that receives a PathEvent for every change
+// Refresh discovers. The channel is buffered; if a consumer falls behind,
+// events are dropped rather than blocking refresh.
+func (c *Client) Subscribe() <-chan PathEvent {
+	ch := make(chan PathEvent, 64)
+	c.subsMu.Lock()
+	c.subs = append(c.subs, ch)
+	c.subsMu.Unlock()
+	return ch
+}
+
+func (c *Client) emit(events []PathEvent) {
+	if len(events) == 0 {
+		return
+	}
+	c.subsMu.Lock()
+	subs := append([]chan PathEvent(nil), c.subs...)
+	c.subsMu.Unlock()
+
+	for _, ch := range subs {
+		for _, ev := range events {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// Refresh re-fetches the path index (using If-None-Match/If-Modified-Since
+// so an unchanged index costs a 304 instead of a full body) and reconciles
+// it against the cached index and entries, emitting a PathEvent for every
+// path that appeared, moved to a different uuid, or disappeared. Stale
+// entries are evicted from the cache so the next read refetches them.
+// Cached entries whose path is unchanged are refetched in one batch, and
+// each whose IdxEdited changed emits a PathModified event with an empty Path.
+//
+// If RefreshInterval is non-zero, Refresh also starts a background goroutine
+// that repeats this every RefreshInterval until ctx is done.
+func (c *Client) Refresh(ctx context.Context) error {
+	if err := c.refreshOnce(ctx); err != nil {
+		return err
+	}
+	if c.RefreshInterval > 0 {
+		go c.refreshLoop(ctx)
+	}
+	return nil
+}
+
+func (c *Client) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = c.refreshOnce(ctx)
+		}
+	}
+}
+
+func (c *Client) refreshOnce(ctx context.Context) error {
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	loaded := c.loaded
+	c.unlock()
+	if !loaded {
+		return c.loadIndexContext(ctx)
+	}
+
+	var raw map[string]any
+	if err := c.request(ctx, "GET", pathIndexEndpoint, nil, &raw); err != nil {
+		if errors.Is(err, errNotModified) {
+			return nil
+		}
+		return err
+	}
+
+	indexData, ok := raw["index"].(map[string]any)
+	if !ok {
+		return errors.New("invalid index response")
+	}
+
+	next := make(map[string]string, len(indexData))
+	for k, v := range indexData {
+		vStr, ok := v.(string)
+		if !ok {
+			continue
+		}
+		next[cleanPath(k)] = vStr
+	}
+
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+
+	// Entries staged in c.dirty (created/renamed/removed locally but not
+	// yet Committed) haven't reached the server yet, so the index it just
+	// returned can't be trusted for them: it may still be missing a local
+	// create, still list a path under its pre-rename name, or still list a
+	// local delete. For each such uuid, trust the client's own (path, uuid)
+	// pairing over the server's rather than just checking the uuid is
+	// present somewhere in the index - otherwise a stale server entry for
+	// the uuid's old path survives alongside the new one.
+	pendingUUIDs := make(map[string]struct{}, len(c.dirty))
+	for _, ch := range c.dirty {
+		pendingUUIDs[ch.UUID] = struct{}{}
+	}
+
+	merged := make(map[string]string, len(next))
+	for p, uuid := range next {
+		if _, pending := pendingUUIDs[uuid]; pending {
+			if localUUID, ok := c.index[p]; !ok || localUUID != uuid {
+				// Locally renamed away from p, or removed: don't resurrect.
+				continue
+			}
+		}
+		merged[p] = uuid
+	}
+	for p, uuid := range c.index {
+		if _, pending := pendingUUIDs[uuid]; pending {
+			// Created/renamed locally, not committed yet: keep our path.
+			merged[p] = uuid
+		}
+	}
+
+	var events []PathEvent
+	var refetch []string
+
+	for p, uuid := range merged {
+		oldUUID, existed := c.index[p]
+		switch {
+		case !existed:
+			events = append(events, PathEvent{Type: PathCreated, Path: p, UUID: uuid})
+		case oldUUID != uuid:
+			delete(c.entries, oldUUID)
+			events = append(events, PathEvent{Type: PathModified, Path: p, UUID: uuid})
+		default:
+			if _, cached := c.entries[uuid]; cached {
+				refetch = append(refetch, uuid)
+			}
+		}
+	}
+	for p, uuid := range c.index {
+		if _, ok := merged[p]; !ok {
+			delete(c.entries, uuid)
+			events = append(events, PathEvent{Type: PathDeleted, Path: p, UUID: uuid})
+		}
+	}
+
+	c.index = merged
+	c.unlock()
+
+	if len(refetch) > 0 {
+		fresh, err := c.fetchEntriesContext(ctx, refetch)
+		if err == nil {
+			if err := c.lock(ctx); err == nil {
+				for uuid, entry := range fresh {
+					cached, ok := c.entries[uuid]
+					changed := ok && idxEditedMillis(cached) != idxEditedMillis(entry)
+					c.entries[uuid] = entry
+					if changed {
+						events = append(events, PathEvent{Type: PathModified, Path: "", UUID: uuid})
+					}
+				}
+				c.unlock()
+			}
+		}
+	}
+
+	c.emit(events)
+	return nil
+}
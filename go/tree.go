@@ -0,0 +1,209 @@
+package originFSKit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// descendantsOf returns the index paths (with their uuids) that lie strictly
+// under the folder at p, found by prefix-scanning c.index. Callers must hold
+// the client's lock (see Client.lock).
+func (c *Client) descendantsOf(p string) (paths []string, uuids []string) {
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	for other, uuid := range c.index {
+		if strings.HasPrefix(other, prefix) {
+			paths = append(paths, other)
+			uuids = append(uuids, uuid)
+		}
+	}
+	return paths, uuids
+}
+
+// CopyTree deep-copies src (a file or a folder and everything under it) to
+// dst. Every copied entry gets a fresh uuid and is staged as a UUIDa change;
+// call Commit to persist the whole subtree in one request.
+func (c *Client) CopyTree(src, dst string) error {
+	return c.CopyTreeContext(context.Background(), src, dst)
+}
+
+func (c *Client) CopyTreeContext(ctx context.Context, src, dst string) error {
+	if err := c.loadIndexContext(ctx); err != nil {
+		return err
+	}
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	defer c.unlock()
+
+	src = strings.ToLower(src)
+	dst = strings.ToLower(dst)
+
+	uuid, ok := c.index[src]
+	if !ok {
+		return errors.New("not found")
+	}
+
+	childPaths, childUUIDs := c.descendantsOf(src)
+	if err := c.ensureEntryContext(ctx, uuid); err != nil {
+		return err
+	}
+	if err := c.ensureEntriesContext(ctx, childUUIDs); err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	newUUID := generateUUID(c.username)
+	rootEntry := clone(c.entries[uuid])
+	relocate(rootEntry, dst, c.username)
+	rootEntry[IdxCreated] = now
+	rootEntry[IdxEdited] = now
+	rootEntry[IdxUUID] = newUUID
+	c.entries[newUUID] = rootEntry
+	c.index[dst] = newUUID
+	c.dirty = append(c.dirty, UpdateChange{Command: "UUIDa", UUID: newUUID, Dta: rootEntry})
+
+	srcPrefix := strings.TrimSuffix(src, "/")
+	for i, oldChild := range childPaths {
+		childUUID := childUUIDs[i]
+		newChild := dst + strings.TrimPrefix(oldChild, srcPrefix)
+		newChildUUID := generateUUID(c.username)
+
+		childEntry := clone(c.entries[childUUID])
+		relocateDescendant(childEntry, newChild, c.username)
+		childEntry[IdxCreated] = now
+		childEntry[IdxEdited] = now
+		childEntry[IdxUUID] = newChildUUID
+		c.entries[newChildUUID] = childEntry
+		c.index[newChild] = newChildUUID
+		c.dirty = append(c.dirty, UpdateChange{Command: "UUIDa", UUID: newChildUUID, Dta: childEntry})
+	}
+	return nil
+}
+
+// MoveTree relocates src (a file or a folder and everything under it) to
+// dst, rewriting IdxLocation and the index keys of every descendant so none
+// of them are orphaned the way a plain Rename would leave them.
+func (c *Client) MoveTree(src, dst string) error {
+	return c.MoveTreeContext(context.Background(), src, dst)
+}
+
+func (c *Client) MoveTreeContext(ctx context.Context, src, dst string) error {
+	if err := c.loadIndexContext(ctx); err != nil {
+		return err
+	}
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	defer c.unlock()
+
+	src = strings.ToLower(src)
+	dst = strings.ToLower(dst)
+
+	uuid, ok := c.index[src]
+	if !ok {
+		return errors.New("not found")
+	}
+
+	childPaths, childUUIDs := c.descendantsOf(src)
+	if err := c.ensureEntryContext(ctx, uuid); err != nil {
+		return err
+	}
+	if err := c.ensureEntriesContext(ctx, childUUIDs); err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+
+	e := c.entries[uuid]
+	relocate(e, dst, c.username)
+	e[IdxEdited] = now
+	c.entries[uuid] = e
+	delete(c.index, src)
+	c.index[dst] = uuid
+	c.dirty = append(c.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: e[IdxType], Idx: IdxType + 1})
+	c.dirty = append(c.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: e[IdxName], Idx: IdxName + 1})
+	c.dirty = append(c.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: e[IdxLocation], Idx: IdxLocation + 1})
+	c.dirty = append(c.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: now, Idx: IdxEdited + 1})
+
+	srcPrefix := strings.TrimSuffix(src, "/")
+	for i, oldChild := range childPaths {
+		childUUID := childUUIDs[i]
+		newChild := dst + strings.TrimPrefix(oldChild, srcPrefix)
+
+		ce := c.entries[childUUID]
+		relocateDescendant(ce, newChild, c.username)
+		ce[IdxEdited] = now
+		c.entries[childUUID] = ce
+		delete(c.index, oldChild)
+		c.index[newChild] = childUUID
+		c.dirty = append(c.dirty, UpdateChange{Command: "UUIDr", UUID: childUUID, Dta: ce[IdxLocation], Idx: IdxLocation + 1})
+		c.dirty = append(c.dirty, UpdateChange{Command: "UUIDr", UUID: childUUID, Dta: now, Idx: IdxEdited + 1})
+	}
+	return nil
+}
+
+// RemoveAll deletes p and, if it is a folder, every descendant found by
+// prefix-scanning the index, batching one UUIDd change per removed entry.
+func (c *Client) RemoveAll(p string) error {
+	return c.RemoveAllContext(context.Background(), p)
+}
+
+func (c *Client) RemoveAllContext(ctx context.Context, p string) error {
+	if err := c.loadIndexContext(ctx); err != nil {
+		return err
+	}
+	if err := c.lock(ctx); err != nil {
+		return err
+	}
+	defer c.unlock()
+
+	p = strings.ToLower(p)
+	uuid, ok := c.index[p]
+	if !ok {
+		return errors.New("not found")
+	}
+
+	childPaths, childUUIDs := c.descendantsOf(p)
+	for i, childPath := range childPaths {
+		childUUID := childUUIDs[i]
+		delete(c.index, childPath)
+		delete(c.entries, childUUID)
+		c.dirty = append(c.dirty, UpdateChange{Command: "UUIDd", UUID: childUUID})
+	}
+
+	delete(c.index, p)
+	delete(c.entries, uuid)
+	c.dirty = append(c.dirty, UpdateChange{Command: "UUIDd", UUID: uuid})
+	return nil
+}
+
+// relocate rewrites a top-level entry's name and location for a move or copy
+// to newPath. A file also gets its type from newPath's extension and a full
+// "origin/(c) users/..." location, the same way Rename does; a folder keeps
+// its ".folder" type and gets the bare parent directory as its location.
+func relocate(e FileEntry, newPath string, username string) {
+	dir, file := path.Split(newPath)
+
+	if fmt.Sprint(e[IdxType]) == ".folder" {
+		e[IdxName] = strings.TrimSuffix(file, path.Ext(file))
+		e[IdxLocation] = strings.TrimSuffix(dir, "/")
+		return
+	}
+
+	ext := path.Ext(file)
+	e[IdxType] = ext
+	e[IdxName] = strings.TrimSuffix(file, ext)
+	e[IdxLocation] = "origin/(c) users/" + username + "/" + strings.TrimPrefix(strings.TrimSuffix(dir, "/"), "/")
+}
+
+// relocateDescendant rewrites only the location of a descendant entry being
+// moved or copied along with its parent folder; its own name and type are
+// unaffected by the ancestor's rename.
+func relocateDescendant(e FileEntry, newPath string, username string) {
+	dir, _ := path.Split(newPath)
+	e[IdxLocation] = "origin/(c) users/" + username + "/" + strings.TrimPrefix(strings.TrimSuffix(dir, "/"), "/")
+}
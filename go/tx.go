@@ -0,0 +1,342 @@
+package originFSKit
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrConflict is returned by Tx.Commit when a uuid the transaction touched
+// was modified (its IdxEdited changed) by something else since Begin.
+// Callers should retry the whole transaction against fresh state.
+var ErrConflict = errors.New("originfskit: conflicting update, retry transaction")
+
+// Tx is a staged batch of mutations against a Client. It holds its own copy
+// of index/entries cloned at Begin time, so CreateFile, WriteFile, Rename,
+// Remove and CreateFolder can be called repeatedly and only take effect on
+// the Client (and the server) when Commit succeeds. Rollback discards
+// everything staged so far.
+type Tx struct {
+	c *Client
+
+	mu      sync.Mutex
+	index   map[string]string
+	entries map[string]FileEntry
+	dirty   []UpdateChange
+	touched map[string]int64
+	apply   []func(c *Client)
+	done    bool
+}
+
+// Begin starts a transaction against c, snapshotting its current index and
+// entries.
+func (c *Client) Begin() (*Tx, error) {
+	if err := c.loadIndex(); err != nil {
+		return nil, err
+	}
+	if err := c.lock(context.Background()); err != nil {
+		return nil, err
+	}
+	defer c.unlock()
+
+	tx := &Tx{
+		c:       c,
+		index:   make(map[string]string, len(c.index)),
+		entries: make(map[string]FileEntry, len(c.entries)),
+		touched: make(map[string]int64),
+	}
+	for k, v := range c.index {
+		tx.index[k] = v
+	}
+	for k, v := range c.entries {
+		tx.entries[k] = clone(v)
+	}
+	return tx, nil
+}
+
+// markTouched records the IdxEdited value the transaction observed for uuid
+// the first time it is mutated, so Commit can detect if something else
+// changed it in the meantime.
+func (tx *Tx) markTouched(uuid string) {
+	if _, ok := tx.touched[uuid]; ok {
+		return
+	}
+	if e, ok := tx.entries[uuid]; ok {
+		tx.touched[uuid] = idxEditedMillis(e)
+	}
+}
+
+func (tx *Tx) ensureEntry(uuid string) error {
+	if _, ok := tx.entries[uuid]; ok {
+		return nil
+	}
+	var entry FileEntry
+	if err := tx.c.request(context.Background(), "GET", "/files/by-uuid?uuid="+uuid, nil, &entry); err != nil {
+		return err
+	}
+	tx.entries[uuid] = entry
+	return nil
+}
+
+func (tx *Tx) createFolders(dir string) {
+	dir = strings.TrimSuffix(dir, "/")
+	if dir == "" || dir == "/" {
+		return
+	}
+
+	parts := strings.Split(dir, "/")
+	for i := 1; i <= len(parts); i++ {
+		subPath := strings.ToLower(path.Join(parts[:i]...))
+		if !strings.HasPrefix(subPath, "/") {
+			subPath = "/" + subPath
+		}
+		if _, ok := tx.index[subPath]; ok {
+			continue
+		}
+
+		now := time.Now().UnixMilli()
+		uuid := generateUUID(tx.c.username)
+		entry := make(FileEntry, entrySize)
+		entry[IdxType] = ".folder"
+		entry[IdxName] = parts[i-1]
+		entry[IdxLocation] = "origin/(c) users/" + tx.c.username + "/" + strings.TrimPrefix(strings.TrimSuffix(strings.Join(parts[:i-1], "/"), "/"), "/")
+		entry[IdxData] = []any{}
+		entry[IdxCreated] = now
+		entry[IdxEdited] = now
+		entry[IdxSize] = 0
+		entry[IdxUUID] = uuid
+		tx.entries[uuid] = entry
+		tx.index[subPath] = uuid
+		tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDa", UUID: uuid, Dta: entry})
+
+		folderPath, folderEntry, folderUUID := subPath, entry, uuid
+		tx.apply = append(tx.apply, func(c *Client) {
+			c.index[folderPath] = folderUUID
+			c.entries[folderUUID] = folderEntry
+		})
+	}
+}
+
+// CreateFile stages a new file under the transaction.
+func (tx *Tx) CreateFile(p string, data string) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	p = strings.ToLower(p)
+	now := time.Now().UnixMilli()
+	dir, file := path.Split(p)
+	ext := path.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+
+	tx.createFolders(dir)
+
+	uuid := generateUUID(tx.c.username)
+	entry := make(FileEntry, entrySize)
+	entry[IdxType] = ext
+	entry[IdxName] = name
+	entry[IdxLocation] = "origin/(c) users/" + tx.c.username + "/" + strings.TrimPrefix(strings.TrimSuffix(dir, "/"), "/")
+	entry[IdxData] = data
+	entry[IdxCreated] = now
+	entry[IdxEdited] = now
+	entry[IdxSize] = len(data)
+	entry[IdxUUID] = uuid
+	tx.entries[uuid] = entry
+	tx.index[p] = uuid
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDa", UUID: uuid, Dta: entry})
+
+	tx.apply = append(tx.apply, func(c *Client) {
+		c.index[p] = uuid
+		c.entries[uuid] = entry
+	})
+	return nil
+}
+
+// CreateFolder stages a new folder under the transaction.
+func (tx *Tx) CreateFolder(p string) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	p = strings.ToLower(p)
+	now := time.Now().UnixMilli()
+	dir, file := path.Split(p)
+	ext := path.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+
+	tx.createFolders(dir)
+
+	uuid := generateUUID(tx.c.username)
+	entry := make(FileEntry, entrySize)
+	entry[IdxType] = ".folder"
+	entry[IdxName] = name
+	entry[IdxLocation] = strings.TrimSuffix(dir, "/")
+	entry[IdxData] = []any{}
+	entry[IdxCreated] = now
+	entry[IdxEdited] = now
+	entry[IdxSize] = 0
+	entry[IdxUUID] = uuid
+	tx.entries[uuid] = entry
+	tx.index[p] = uuid
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDa", UUID: uuid, Dta: entry})
+
+	tx.apply = append(tx.apply, func(c *Client) {
+		c.index[p] = uuid
+		c.entries[uuid] = entry
+	})
+	return nil
+}
+
+// WriteFile stages new content for an existing file.
+func (tx *Tx) WriteFile(p string, data string) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	p = strings.ToLower(p)
+	uuid, ok := tx.index[p]
+	if !ok {
+		return errors.New("create via CreateFile")
+	}
+	if err := tx.ensureEntry(uuid); err != nil {
+		return err
+	}
+	tx.markTouched(uuid)
+
+	now := time.Now().UnixMilli()
+	e := tx.entries[uuid]
+	e[IdxData] = data
+	e[IdxEdited] = now
+	e[IdxSize] = len(data)
+	tx.entries[uuid] = e
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: data, Idx: IdxData + 1})
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: now, Idx: IdxEdited + 1})
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: len(data), Idx: IdxSize + 1})
+
+	tx.apply = append(tx.apply, func(c *Client) {
+		c.entries[uuid] = e
+	})
+	return nil
+}
+
+// Rename stages a move/rename of an existing entry.
+func (tx *Tx) Rename(oldPath, newPath string) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	oldPath = strings.ToLower(oldPath)
+	uuid, ok := tx.index[oldPath]
+	if !ok {
+		return errors.New("not found")
+	}
+	if err := tx.ensureEntry(uuid); err != nil {
+		return err
+	}
+	tx.markTouched(uuid)
+
+	e := tx.entries[uuid]
+	dir, file := path.Split(newPath)
+	ext := path.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+	now := time.Now().UnixMilli()
+	e[IdxType] = ext
+	e[IdxName] = name
+	e[IdxLocation] = "origin/(c) users/" + tx.c.username + "/" + strings.TrimPrefix(strings.TrimSuffix(dir, "/"), "/")
+	e[IdxEdited] = now
+	tx.entries[uuid] = e
+	delete(tx.index, oldPath)
+	tx.index[strings.ToLower(newPath)] = uuid
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: ext, Idx: IdxType + 1})
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: name, Idx: IdxName + 1})
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: e[IdxLocation], Idx: IdxLocation + 1})
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDr", UUID: uuid, Dta: now, Idx: IdxEdited + 1})
+
+	newPathLower := strings.ToLower(newPath)
+	tx.apply = append(tx.apply, func(c *Client) {
+		delete(c.index, oldPath)
+		c.index[newPathLower] = uuid
+		c.entries[uuid] = e
+	})
+	return nil
+}
+
+// Remove stages the deletion of an entry.
+func (tx *Tx) Remove(p string) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	p = strings.ToLower(p)
+	uuid, ok := tx.index[p]
+	if !ok {
+		return errors.New("not found")
+	}
+	if err := tx.ensureEntry(uuid); err != nil {
+		return err
+	}
+	tx.markTouched(uuid)
+
+	delete(tx.index, p)
+	delete(tx.entries, uuid)
+	tx.dirty = append(tx.dirty, UpdateChange{Command: "UUIDd", UUID: uuid})
+
+	tx.apply = append(tx.apply, func(c *Client) {
+		delete(c.index, p)
+		delete(c.entries, uuid)
+	})
+	return nil
+}
+
+// Commit applies all staged mutations to the underlying Client and flushes
+// them to the server in one call, failing with ErrConflict if any touched
+// uuid was edited since Begin.
+func (tx *Tx) Commit() error {
+	return tx.CommitContext(context.Background())
+}
+
+func (tx *Tx) CommitContext(ctx context.Context) error {
+	tx.mu.Lock()
+	if tx.done {
+		tx.mu.Unlock()
+		return errors.New("originfskit: transaction already finished")
+	}
+	tx.done = true
+	apply := tx.apply
+	dirty := tx.dirty
+	touched := tx.touched
+	tx.mu.Unlock()
+
+	if err := tx.c.lock(ctx); err != nil {
+		return err
+	}
+	defer tx.c.unlock()
+	for uuid, observedEdited := range touched {
+		current, ok := tx.c.entries[uuid]
+		if !ok {
+			continue
+		}
+		if idxEditedMillis(current) != observedEdited {
+			return ErrConflict
+		}
+	}
+
+	if len(dirty) > 0 {
+		var res UpdateResult
+		if err := tx.c.request(ctx, "POST", "/files", UpdateFileRequest{Updates: dirty}, &res); err != nil {
+			return err
+		}
+	}
+	for _, op := range apply {
+		op(tx.c)
+	}
+	return nil
+}
+
+// Rollback discards every mutation staged so far. It is safe to call after
+// Commit has already run (it is then a no-op).
+func (tx *Tx) Rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.done = true
+	tx.apply = nil
+	tx.dirty = nil
+}
@@ -0,0 +1,235 @@
+package originFSKit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/net/webdav"
+)
+
+// WebDAVFS adapts a Client to golang.org/x/net/webdav.FileSystem, so the
+// account it was created for can be mounted with any WebDAV client (macOS
+// Finder, Windows Explorer, davfs2, ...).
+type WebDAVFS struct {
+	c *Client
+}
+
+var _ webdav.FileSystem = WebDAVFS{}
+
+// Handler returns a ready-to-use *webdav.Handler backed by c, serving at
+// prefix. Locking has no native equivalent in rotur, so an in-memory
+// webdav.LockSystem is used.
+func (c *Client) Handler(prefix string) *webdav.Handler {
+	return &webdav.Handler{
+		Prefix:     prefix,
+		FileSystem: WebDAVFS{c: c},
+		LockSystem: webdav.NewMemLS(),
+	}
+}
+
+func (fs WebDAVFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	p := fs.c.JoinPath(name)
+	if fs.c.ExistsContext(ctx, p) {
+		return os.ErrExist
+	}
+	if err := fs.c.CreateFolderContext(ctx, p); err != nil {
+		return err
+	}
+	return fs.c.CommitContext(ctx)
+}
+
+func (fs WebDAVFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	p := fs.c.JoinPath(name)
+	exists := fs.c.ExistsContext(ctx, p)
+
+	if !exists {
+		if flag&os.O_CREATE == 0 {
+			return nil, os.ErrNotExist
+		}
+		if err := fs.c.CreateFileContext(ctx, p, ""); err != nil {
+			return nil, err
+		}
+		if err := fs.c.CommitContext(ctx); err != nil {
+			return nil, err
+		}
+		return &webdavFile{c: fs.c, ctx: ctx, path: p, buf: bytes.NewBuffer(nil), dirty: true}, nil
+	}
+
+	if flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0 {
+		return nil, os.ErrExist
+	}
+
+	entry, err := fs.c.ReadFileContext(ctx, p)
+	if err != nil {
+		return nil, translateErr(err)
+	}
+	info := entryInfoFromEntry(entry)
+	if info.IsDir() {
+		return &webdavFile{c: fs.c, ctx: ctx, path: p, info: info, isDir: true}, nil
+	}
+
+	content := ""
+	if flag&os.O_TRUNC == 0 {
+		content, err = fs.c.ReadFileContentContext(ctx, p)
+		if err != nil {
+			return nil, translateErr(err)
+		}
+	}
+
+	return &webdavFile{
+		c:     fs.c,
+		ctx:   ctx,
+		path:  p,
+		buf:   bytes.NewBuffer([]byte(content)),
+		info:  info,
+		dirty: flag&os.O_TRUNC != 0,
+	}, nil
+}
+
+// RemoveAll removes name and, if it is a folder, every descendant.
+func (fs WebDAVFS) RemoveAll(ctx context.Context, name string) error {
+	p := fs.c.JoinPath(name)
+	if err := fs.c.RemoveAllContext(ctx, p); err != nil {
+		return translateErr(err)
+	}
+	return fs.c.CommitContext(ctx)
+}
+
+// Rename relocates the named entry and, if it is a folder, every descendant.
+func (fs WebDAVFS) Rename(ctx context.Context, oldName, newName string) error {
+	oldPath := fs.c.JoinPath(oldName)
+	newPath := fs.c.JoinPath(newName)
+	if err := fs.c.MoveTreeContext(ctx, oldPath, newPath); err != nil {
+		return translateErr(err)
+	}
+	return fs.c.CommitContext(ctx)
+}
+
+func (fs WebDAVFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	p := fs.c.JoinPath(name)
+	if p == "/" {
+		return &entryInfo{name: ".", isDir: true}, nil
+	}
+	entry, err := fs.c.ReadFileContext(ctx, p)
+	if err != nil {
+		return nil, translateErr(err)
+	}
+	return entryInfoFromEntry(entry), nil
+}
+
+// webdavFile implements webdav.File (http.File + io.Writer) over an
+// in-memory copy of a rotur file's content, flushed back on Close.
+type webdavFile struct {
+	c      *Client
+	ctx    context.Context
+	path   string
+	buf    *bytes.Buffer
+	off    int64
+	info   *entryInfo
+	isDir  bool
+	dirty  bool
+	closed bool
+}
+
+func (f *webdavFile) Close() error {
+	if f.closed || !f.dirty {
+		f.closed = true
+		return nil
+	}
+	f.closed = true
+
+	content := f.buf.String()
+	if f.c.ExistsContext(f.ctx, f.path) {
+		if err := f.c.WriteFileContext(f.ctx, f.path, content); err != nil {
+			return err
+		}
+	} else if err := f.c.CreateFileContext(f.ctx, f.path, content); err != nil {
+		return err
+	}
+	return f.c.CommitContext(f.ctx)
+}
+
+func (f *webdavFile) Read(p []byte) (int, error) {
+	if f.isDir {
+		return 0, os.ErrInvalid
+	}
+	b := f.buf.Bytes()
+	if f.off >= int64(len(b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, b[f.off:])
+	f.off += int64(n)
+	return n, nil
+}
+
+func (f *webdavFile) Write(p []byte) (int, error) {
+	if f.isDir {
+		return 0, os.ErrInvalid
+	}
+	b := f.buf.Bytes()
+	end := f.off + int64(len(p))
+	if end > int64(len(b)) {
+		grown := make([]byte, end)
+		copy(grown, b)
+		b = grown
+	}
+	copy(b[f.off:end], p)
+	f.buf = bytes.NewBuffer(b)
+	f.off = end
+	f.dirty = true
+	return len(p), nil
+}
+
+func (f *webdavFile) Seek(offset int64, whence int) (int64, error) {
+	var base int64
+	switch whence {
+	case os.SEEK_SET:
+		base = 0
+	case os.SEEK_CUR:
+		base = f.off
+	case os.SEEK_END:
+		base = int64(f.buf.Len())
+	default:
+		return 0, os.ErrInvalid
+	}
+	f.off = base + offset
+	return f.off, nil
+}
+
+func (f *webdavFile) Stat() (os.FileInfo, error) {
+	if f.isDir {
+		return f.info, nil
+	}
+	if f.info == nil {
+		f.info = &entryInfo{name: path.Base(f.path), size: int64(f.buf.Len()), modTime: time.Now()}
+	}
+	return &entryInfo{
+		name:    f.info.name,
+		size:    int64(f.buf.Len()),
+		modTime: time.Now(),
+		isDir:   false,
+	}, nil
+}
+
+func (f *webdavFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.isDir {
+		return nil, os.ErrInvalid
+	}
+	children := f.c.ListDirContext(f.ctx, f.path)
+	prefix := strings.TrimSuffix(f.path, "/")
+
+	out := make([]os.FileInfo, 0, len(children))
+	for _, child := range children {
+		entry, err := f.c.ReadFileContext(f.ctx, prefix+"/"+child)
+		if err != nil {
+			return nil, translateErr(err)
+		}
+		out = append(out, entryInfoFromEntry(entry))
+	}
+	return out, nil
+}